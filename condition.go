@@ -0,0 +1,413 @@
+package dbf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Operator identifies a comparison a Condition leaf performs against a field.
+type Operator string
+
+// Operators understood by Condition. "in" and "between" take more than one
+// value; the rest take exactly one, except "isnull" which takes none.
+const (
+	OpEq         Operator = "="
+	OpNe         Operator = "!="
+	OpLt         Operator = "<"
+	OpLe         Operator = "<="
+	OpGt         Operator = ">"
+	OpGe         Operator = ">="
+	OpIn         Operator = "in"
+	OpBetween    Operator = "between"
+	OpLike       Operator = "like"
+	OpStartsWith Operator = "startswith"
+	OpEndsWith   Operator = "endswith"
+	OpContains   Operator = "contains"
+	OpIsNull     Operator = "isnull"
+)
+
+//clause is one entry of a Condition: either a leaf comparison against a
+//field, or a nested Condition grafted in as a single operand.
+type clause struct {
+	join  string // "and" or "or" - how this clause combines with clauses before it; ignored on the first clause
+	not   bool
+	field string
+	op    Operator
+	args  []interface{}
+	group *Condition
+}
+
+//Condition is a composable WHERE-style predicate tree, built by chaining
+//And/AndNot/Or calls, similar in spirit to Beego ORM's Condition type.
+//Condition is immutable: every builder method returns a new *Condition,
+//leaving the receiver untouched.
+type Condition struct {
+	clauses []clause
+}
+
+//NewCondition returns an empty Condition.
+func NewCondition() *Condition {
+	return &Condition{}
+}
+
+func (c *Condition) append(cl clause) *Condition {
+	nc := &Condition{clauses: make([]clause, len(c.clauses), len(c.clauses)+1)}
+	copy(nc.clauses, c.clauses)
+	nc.clauses = append(nc.clauses, cl)
+	return nc
+}
+
+//And ANDs a field comparison onto the condition, e.g. And("age", OpGt, 30).
+//"in" and "between" take multiple args; "isnull" takes none.
+func (c *Condition) And(field string, op Operator, args ...interface{}) *Condition {
+	return c.append(clause{join: "and", field: field, op: op, args: args})
+}
+
+//AndNot ANDs the negation of a field comparison onto the condition.
+func (c *Condition) AndNot(field string, op Operator, args ...interface{}) *Condition {
+	return c.append(clause{join: "and", not: true, field: field, op: op, args: args})
+}
+
+//Or ORs a nested Condition group onto the condition.
+func (c *Condition) Or(sub *Condition) *Condition {
+	return c.append(clause{join: "or", group: sub})
+}
+
+//OrNot ORs the negation of a nested Condition group onto the condition.
+func (c *Condition) OrNot(sub *Condition) *Condition {
+	return c.append(clause{join: "or", not: true, group: sub})
+}
+
+//isPureAnd reports whether every clause is a plain, AND-joined leaf, so the
+//whole condition can be evaluated field-by-field during the scan, bailing
+//out as soon as any clause fails instead of waiting for the full record.
+func (c *Condition) isPureAnd() bool {
+	for _, cl := range c.clauses {
+		if cl.group != nil || cl.join == "or" {
+			return false
+		}
+	}
+	return true
+}
+
+//fields returns the set of field names referenced anywhere in the
+//condition, including nested groups.
+func (c *Condition) fields() map[string]bool {
+	out := make(map[string]bool)
+	c.collectFields(out)
+	return out
+}
+
+func (c *Condition) collectFields(out map[string]bool) {
+	if c == nil {
+		return
+	}
+	for _, cl := range c.clauses {
+		if cl.group != nil {
+			cl.group.collectFields(out)
+			continue
+		}
+		out[cl.field] = true
+	}
+}
+
+//leavesForField returns the leaf clauses of a pure-AND condition that test
+//the given field, used for the field-by-field fast path in Read.
+func (c *Condition) leavesForField(field string) []clause {
+	var out []clause
+	for _, cl := range c.clauses {
+		if cl.field == field {
+			out = append(out, cl)
+		}
+	}
+	return out
+}
+
+//eval evaluates the full condition against a decoded record.
+func (c *Condition) eval(rec Record) (bool, error) {
+	if c == nil || len(c.clauses) == 0 {
+		return true, nil
+	}
+	var acc bool
+	for i, cl := range c.clauses {
+		v, err := evalClause(cl, rec)
+		if err != nil {
+			return false, err
+		}
+		if i == 0 {
+			acc = v
+			continue
+		}
+		if cl.join == "or" {
+			acc = acc || v
+		} else {
+			acc = acc && v
+		}
+	}
+	return acc, nil
+}
+
+func evalClause(cl clause, rec Record) (bool, error) {
+	var result bool
+	var err error
+	if cl.group != nil {
+		result, err = cl.group.eval(rec)
+	} else {
+		result, err = evalLeaf(cl, rec[cl.field])
+	}
+	if err != nil {
+		return false, err
+	}
+	if cl.not {
+		result = !result
+	}
+	return result, nil
+}
+
+func evalLeaf(cl clause, fieldValue interface{}) (bool, error) {
+	if cl.op == OpIsNull {
+		return fieldValue == nil, nil
+	}
+	if fieldValue == nil {
+		return false, nil
+	}
+	switch cl.op {
+	case OpIn:
+		for _, a := range cl.args {
+			eq, err := valuesEqual(fieldValue, a)
+			if err != nil {
+				return false, err
+			}
+			if eq {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpBetween:
+		if len(cl.args) != 2 {
+			return false, fmt.Errorf("dbf: %q requires exactly 2 values for field %s", OpBetween, cl.field)
+		}
+		lo, err := compareValues(fieldValue, cl.args[0])
+		if err != nil {
+			return false, err
+		}
+		hi, err := compareValues(fieldValue, cl.args[1])
+		if err != nil {
+			return false, err
+		}
+		return lo >= 0 && hi <= 0, nil
+	case OpLike, OpStartsWith, OpEndsWith, OpContains:
+		return evalStringOp(cl, fieldValue)
+	default:
+		if len(cl.args) != 1 {
+			return false, fmt.Errorf("dbf: operator %q requires exactly 1 value for field %s", cl.op, cl.field)
+		}
+		c, err := compareValues(fieldValue, cl.args[0])
+		if err != nil {
+			return false, err
+		}
+		switch cl.op {
+		case OpEq:
+			return c == 0, nil
+		case OpNe:
+			return c != 0, nil
+		case OpLt:
+			return c < 0, nil
+		case OpLe:
+			return c <= 0, nil
+		case OpGt:
+			return c > 0, nil
+		case OpGe:
+			return c >= 0, nil
+		default:
+			return false, fmt.Errorf("dbf: unsupported operator %q for field %s", cl.op, cl.field)
+		}
+	}
+}
+
+func evalStringOp(cl clause, fieldValue interface{}) (bool, error) {
+	s, ok := fieldValue.(string)
+	if !ok {
+		return false, fmt.Errorf("dbf: operator %q only applies to string fields, got %T for field %s", cl.op, fieldValue, cl.field)
+	}
+	if len(cl.args) != 1 {
+		return false, fmt.Errorf("dbf: operator %q requires exactly 1 value for field %s", cl.op, cl.field)
+	}
+	arg, ok := cl.args[0].(string)
+	if !ok {
+		return false, fmt.Errorf("dbf: operator %q requires a string value for field %s", cl.op, cl.field)
+	}
+	ls, la := strings.ToLower(s), strings.ToLower(arg)
+	switch cl.op {
+	case OpStartsWith:
+		return strings.HasPrefix(ls, la), nil
+	case OpEndsWith:
+		return strings.HasSuffix(ls, la), nil
+	case OpContains:
+		return strings.Contains(ls, la), nil
+	case OpLike:
+		return likeMatch(ls, la), nil
+	default:
+		return false, fmt.Errorf("dbf: unsupported operator %q for field %s", cl.op, cl.field)
+	}
+}
+
+//likeMatch implements SQL LIKE matching with '%' (any run of characters)
+//and '_' (any single character) wildcards; s and pattern are assumed to
+//already be case-folded by the caller.
+func likeMatch(s, pattern string) bool {
+	return likeMatchRunes([]rune(s), []rune(pattern))
+}
+
+func likeMatchRunes(s, pattern []rune) bool {
+	if len(pattern) == 0 {
+		return len(s) == 0
+	}
+	switch pattern[0] {
+	case '%':
+		if likeMatchRunes(s, pattern[1:]) {
+			return true
+		}
+		for i := 0; i < len(s); i++ {
+			if likeMatchRunes(s[i+1:], pattern[1:]) {
+				return true
+			}
+		}
+		return false
+	case '_':
+		if len(s) == 0 {
+			return false
+		}
+		return likeMatchRunes(s[1:], pattern[1:])
+	default:
+		if len(s) == 0 || s[0] != pattern[0] {
+			return false
+		}
+		return likeMatchRunes(s[1:], pattern[1:])
+	}
+}
+
+func valuesEqual(fieldValue, arg interface{}) (bool, error) {
+	c, err := compareValues(fieldValue, arg)
+	if err != nil {
+		return false, err
+	}
+	return c == 0, nil
+}
+
+//compareValues orders a decoded field value against a caller-supplied
+//Go value, returning -1, 0 or 1. It performs typed comparisons for string,
+//int, int32, float64 and time.Time field values; strings are compared
+//byte-wise and case-sensitively.
+func compareValues(fieldValue, arg interface{}) (int, error) {
+	switch fv := fieldValue.(type) {
+	case string:
+		av, ok := arg.(string)
+		if !ok {
+			return 0, fmt.Errorf("dbf: expected a string value, got %T", arg)
+		}
+		return strings.Compare(fv, av), nil
+	case int:
+		av, err := toInt(arg)
+		if err != nil {
+			return 0, err
+		}
+		return intCmp(fv, av), nil
+	case int32:
+		av, err := toInt(arg)
+		if err != nil {
+			return 0, err
+		}
+		return intCmp(int(fv), av), nil
+	case float64:
+		av, err := toFloat(arg)
+		if err != nil {
+			return 0, err
+		}
+		return floatCmp(fv, av), nil
+	case time.Time:
+		av, err := toTime(arg)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case fv.Before(av):
+			return -1, nil
+		case fv.After(av):
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	default:
+		return 0, fmt.Errorf("dbf: unsupported field value type %T", fieldValue)
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	case string:
+		return strconv.Atoi(n)
+	default:
+		return 0, fmt.Errorf("dbf: cannot compare %T to an integer field", v)
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("dbf: cannot compare %T to a numeric field", v)
+	}
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		return time.Parse("2006-01-02", t)
+	default:
+		return time.Time{}, fmt.Errorf("dbf: cannot compare %T to a date field", v)
+	}
+}
+
+func intCmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func floatCmp(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
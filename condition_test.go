@@ -0,0 +1,82 @@
+package dbf
+
+import (
+	"os"
+	"testing"
+)
+
+func nameBytes(s string) [11]byte {
+	var b [11]byte
+	copy(b[:], s)
+	return b
+}
+
+func mustWriteTestTable(t *testing.T, path string, fields []Field, records []Record) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := NewWriter(f, fields, 0x03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//TestReadKeepsConditionOnlyFieldsThroughOrEvaluation is a regression test:
+//when the condition contains an OR, a field decoded only because the
+//condition references it (not because it's projected) must still make it
+//into evalLeaf/eval, even though it's stripped from the returned record
+//afterwards.
+func TestReadKeepsConditionOnlyFieldsThroughOrEvaluation(t *testing.T) {
+	path := t.TempDir() + "/people.dbf"
+	fields := []Field{
+		{Name: nameBytes("NAME"), Type: 'C', Len: 10},
+		{Name: nameBytes("AGE"), Type: 'N', Len: 5, DecimalPlaces: 0},
+	}
+	mustWriteTestTable(t, path, fields, []Record{
+		{"NAME": "alice", "AGE": 20},
+		{"NAME": "bob", "AGE": 30},
+	})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only NAME is projected; AGE is referenced solely by the OR condition.
+	r.SetReadFields([]string{"NAME"})
+	r.SetFilter(NewCondition().And("AGE", OpGt, 1000).Or(NewCondition().And("AGE", OpEq, 30)))
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		rec, err := r.Read(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec == nil {
+			continue
+		}
+		if _, ok := rec["AGE"]; ok {
+			t.Errorf("record %d: AGE leaked into the result even though it wasn't projected", i)
+		}
+		got = append(got, rec["NAME"].(string))
+	}
+	if len(got) != 1 || got[0] != "bob" {
+		t.Fatalf("expected [bob], got %v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package dbf
+
+import "io"
+
+//Cursor is a forward-only iterator over a Reader's records. Unlike Read,
+//which always seeks to the requested record (convenient for random access,
+//wasteful for a full scan), Cursor only seeks when it has to - after a
+//record that was fully decoded, the underlying reader is already sitting
+//at the start of the next one.
+type Cursor struct {
+	r             *Reader
+	i             int
+	consumedFully bool
+	rec           Record
+	err           error
+}
+
+//Iterator returns a Cursor that reads sequentially from the start of the
+//table.
+func (r *Reader) Iterator() *Cursor {
+	return &Cursor{r: r}
+}
+
+//Next advances the cursor to the next matching record, applying the same
+//flags and Condition as Read, and reports whether one was found. Once Next
+//returns false, Err should be checked to distinguish a clean end-of-table
+//from a read error.
+func (c *Cursor) Next() bool {
+	c.r.Lock()
+	defer c.r.Unlock()
+
+	for {
+		rec, consumed, err := c.r.readRecord(c.i, !c.consumedFully)
+		c.consumedFully = consumed
+		c.i++
+		if err == io.EOF {
+			// the table ends without a 0x1A marker
+			return false
+		}
+		if err != nil {
+			switch err.(type) {
+			case *EOFError:
+				return false
+			case *SkipError, *DELETEDError:
+				continue
+			default:
+				c.err = err
+				return false
+			}
+		}
+		if rec == nil {
+			// filtered out by the Condition
+			continue
+		}
+		c.rec = rec
+		return true
+	}
+}
+
+//Record returns the record most recently produced by Next.
+func (c *Cursor) Record() Record {
+	return c.rec
+}
+
+//Err returns the first non-EOF error encountered while scanning, if any.
+func (c *Cursor) Err() error {
+	return c.err
+}
@@ -0,0 +1,80 @@
+package dbf
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCursorIteratesAllRecords(t *testing.T) {
+	path := t.TempDir() + "/cursor.dbf"
+	fields := []Field{
+		{Name: nameBytes("NAME"), Type: 'C', Len: 10},
+		{Name: nameBytes("AGE"), Type: 'N', Len: 5, DecimalPlaces: 0},
+	}
+	mustWriteTestTable(t, path, fields, []Record{
+		{"NAME": "alice", "AGE": 20},
+		{"NAME": "bob", "AGE": 30},
+		{"NAME": "carol", "AGE": 40},
+	})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	cur := r.Iterator()
+	for cur.Next() {
+		got = append(got, cur.Record()["NAME"].(string))
+	}
+	if err := cur.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alice", "bob", "carol"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestCursorAppliesFilter(t *testing.T) {
+	path := t.TempDir() + "/cursor-filter.dbf"
+	fields := []Field{
+		{Name: nameBytes("NAME"), Type: 'C', Len: 10},
+		{Name: nameBytes("AGE"), Type: 'N', Len: 5, DecimalPlaces: 0},
+	}
+	mustWriteTestTable(t, path, fields, []Record{
+		{"NAME": "alice", "AGE": 20},
+		{"NAME": "bob", "AGE": 30},
+		{"NAME": "carol", "AGE": 40},
+	})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetFilter(NewCondition().And("AGE", OpGe, 30))
+
+	var got []string
+	cur := r.Iterator()
+	for cur.Next() {
+		got = append(got, cur.Record()["NAME"].(string))
+	}
+	if err := cur.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bob", "carol"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/text/encoding"
 )
 
 // Constants to use with SetFlags, use "or" to combine them (a | b | c... and so on)
@@ -66,31 +68,32 @@ type Reader struct {
 	year, month, day int
 	Length           int // number of records
 	fields           []FilterField
-	headerlen        uint16 // in bytes
-	recordlen        uint16 // length of each record, in bytes
-	flags            int32  //general purpose flags - see constant
+	condition        *Condition
+	memo             *memoFile // sibling .dbt/.fpt file, nil if the table has no memo fields
+	fileVersion      byte      // raw DBF version byte, see supportedVersions
+	enc              encoding.Encoding // codepage used to decode 'C'/memo text, see SetEncoding
+	headerlen        uint16    // in bytes
+	recordlen        uint16    // length of each record, in bytes
+	flags            int32     //general purpose flags - see constant
 	sync.Mutex
 }
 
 type FilterField struct {
-	Filter Filter
-	Read   bool
-	Field  Field
-}
-
-type Filter struct {
-	Condition string
-	Value string
+	Read  bool
+	Field Field
 }
 
 type header struct {
 	// documented at: http://www.clicketyclick.dk/databases/xbase/format/index.html
-	Version    byte
-	Year       uint8 // stored as offset from (decimal) 1900
-	Month, Day uint8
-	Nrec       uint32
-	Headerlen  uint16 // in bytes
-	Recordlen  uint16 // length of each record, in bytes
+	Version        byte
+	Year           uint8 // stored as offset from (decimal) 1900
+	Month, Day     uint8
+	Nrec           uint32
+	Headerlen      uint16 // in bytes
+	Recordlen      uint16 // length of each record, in bytes
+	_              [17]byte // reserved (offsets 0x0C-0x1C)
+	LanguageDriver byte     // codepage id, offset 0x1D - see encodingForLanguageDriver
+	_              [2]byte  // reserved (offsets 0x1E-0x1F)
 }
 
 //NewReader - create a new reader
@@ -102,8 +105,8 @@ func NewReader(r io.ReadSeeker) (*Reader, error) {
 	err := binary.Read(r, binary.LittleEndian, &h)
 	if err != nil {
 		return nil, err
-	} else if h.Version != 0x03 {
-		return nil, fmt.Errorf("unexepected file version: %d", h.Version)
+	} else if _, ok := supportedVersions[h.Version]; !ok {
+		return nil, fmt.Errorf("unexepected file version: %#x", h.Version)
 	}
 	if _, err = r.Seek(0x20, io.SeekStart); err != nil {
 		return nil, err
@@ -137,7 +140,8 @@ func NewReader(r io.ReadSeeker) (*Reader, error) {
 	}
 
 	return &Reader{r, 1900 + int(h.Year),
-		int(h.Month), int(h.Day), int(h.Nrec), filterFields,
+		int(h.Month), int(h.Day), int(h.Nrec), filterFields, nil, nil, h.Version,
+		encodingForLanguageDriver(h.LanguageDriver),
 		h.Headerlen, h.Recordlen, 0, *new(sync.Mutex)}, nil
 }
 
@@ -155,14 +159,24 @@ func (r *Reader) SetReadFields(readFields []string) {
 	}
 }
 
-func (r *Reader) SetFilter(filterFields map[string]Filter)  {
-	for fieldNumber, field := range r.fields {
-		fieldName := r.FieldName(fieldNumber)
-		if filters, fieldHasFilter := filterFields[fieldName]; fieldHasFilter {
-			field.Filter = filters
-		}
-		r.fields[fieldNumber] = field
-	}
+//SetFilter installs a Condition tree to evaluate against every record read.
+//A nil Condition clears any previously set filter.
+func (r *Reader) SetFilter(condition *Condition) {
+	r.condition = condition
+}
+
+//SetEncoding overrides the codepage used to decode 'C'/memo field text and
+//field names, for files whose language driver byte (header offset 0x1D) is
+//missing or wrong. Pass nil to decode as raw bytes, the pre-chunk0-5 behavior.
+func (r *Reader) SetEncoding(enc encoding.Encoding) {
+	r.enc = enc
+}
+
+//Encoding returns the encoding.Encoding currently used to decode string
+//fields, as detected from the header's language driver byte or set via
+//SetEncoding.
+func (r *Reader) Encoding() encoding.Encoding {
+	return r.enc
 }
 
 //ModDate - modification date
@@ -184,13 +198,19 @@ func Tillzero(s []byte) (name string) {
 //FieldName retrieves field name - check for NULL (0x00) termination
 // specs says that it should be 0x00 padded, but it's not always true
 func (r *Reader) FieldName(i int) (name string) {
-	for _, val := range string(r.fields[i].Field.Name[:]) {
-		if val == 0 {
-			return
+	raw := r.fields[i].Field.Name[:]
+	n := len(raw)
+	for j, b := range raw {
+		if b == 0 {
+			n = j
+			break
 		}
-		name = name + string(val)
 	}
-	return
+	decoded, err := r.decodeBytes(raw[:n])
+	if err != nil {
+		return string(raw[:n])
+	}
+	return decoded
 }
 
 //FieldNames get an array with the fields' names
@@ -236,7 +256,7 @@ func (r *Reader) SetFlags(flags int32) int32 {
 //validate - check if it's a valid field type
 func (f *Field) validate() error {
 	switch f.Type {
-	case 'C', 'N', 'F', 'L', 'D', 'I':
+	case 'C', 'N', 'F', 'L', 'D', 'I', 'M', 'T', 'Y', 'B', 'G', 'V':
 		return nil
 	}
 	return fmt.Errorf("Sorry, dbf library doesn't recognize field type '%c', Field: '%s'", f.Type, Tillzero(f.Name[:]))
@@ -267,136 +287,121 @@ func (r *Reader) Read(i int) (rec Record, err error) {
 	r.Lock()
 	defer r.Unlock()
 
+	rec, _, err = r.readRecord(i, true)
+	return rec, err
+}
+
+//readRecord decodes record i. When seekFirst is false, the caller
+//guarantees the underlying reader is already positioned at the start of
+//record i (the case right after a prior call fully consumed record i-1),
+//so the seek to the record's offset is skipped - this is what lets Cursor
+//scan a table without a seek per record. consumedFully reports whether the
+//reader ended up positioned exactly at the start of record i+1, which the
+//caller needs to know to decide whether it can skip the seek next time.
+//The caller must hold r's lock.
+func (r *Reader) readRecord(i int, seekFirst bool) (rec Record, consumedFully bool, err error) {
 	offset := int64(r.headerlen) + int64(r.recordlen)*int64(i)
-	if _, errs := r.r.Seek(offset, io.SeekStart); errs != nil {
-		return nil, errs
+	if seekFirst {
+		if _, errs := r.r.Seek(offset, io.SeekStart); errs != nil {
+			return nil, false, errs
+		}
 	}
 
 	deleted := make([]byte, 1)
 	if _, err = io.ReadFull(r.r, deleted); err != nil {
-		return nil, err
+		return nil, false, err
 	} else if deleted[0] == 0x1a {
 		if r.flags&FlagSkipWeird != 0 {
-			return nil, errSKIP("SKIP")
+			return nil, false, errSKIP("SKIP")
 		}
 		erf := new(EOFError)
 		erf.msg = "EOF"
-		return nil, erf
+		return nil, false, erf
 	} else if deleted[0] == '*' {
 		if r.flags&FlagSkipDeleted != 0 {
-			return nil, errSKIP("SKIP")
+			return nil, false, errSKIP("SKIP")
 		}
 		erd := new(DELETEDError)
 		erd.msg = fmt.Sprintf("Deleted: record %d is deleted", i)
-		return nil, erd
+		return nil, false, erd
 	} else if deleted[0] != ' ' {
-		return nil, fmt.Errorf("Error: Record %d contained an unexpected value in the deleted flag: %x", i, deleted)
+		return nil, false, fmt.Errorf("Error: Record %d contained an unexpected value in the deleted flag: %x", i, deleted)
 	}
 	offset++
 	rec = make(Record)
+
+	//only fields that are projected or referenced by the condition need to
+	//be decoded; everything else is skipped over with a single Seek.
+	var needed map[string]bool
+	pureAnd := true
+	if r.condition != nil {
+		needed = r.condition.fields()
+		pureAnd = r.condition.isPureAnd()
+	}
+
 	for i, field := range r.fields {
 		f := field.Field
 		buf := make([]byte, f.Len)
 		offset = offset + int64(f.Len)
-		if field.Read || field.Filter.Value != "" {
+		name := r.FieldName(i)
+		if field.Read || needed[name] {
 			if _, err = io.ReadFull(r.r, buf); err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			if field.Filter.Value != "" {
-				filtered, err := filterValue(field, buf)
-				if err != nil {
-					return nil, err
-				}
-				if filtered == false {
-					return nil, nil
-				}
+			val, err := r.getFieldValueCasting(f, buf)
+			if err != nil {
+				return nil, false, err
 			}
-			if field.Read {
-				//decodes the field's type, supported: F,N,D,L,C (defaults to string, anyway)
-				rec[r.FieldName(i)], err = getFieldValueCasting(f, buf)
-				if err != nil {
-					return nil, err
+			if pureAnd && r.condition != nil {
+				for _, leaf := range r.condition.leavesForField(name) {
+					ok, err := evalLeaf(leaf, val)
+					if err != nil {
+						return nil, false, err
+					}
+					if leaf.not {
+						ok = !ok
+					}
+					if !ok {
+						//stop decoding this record early - the caller will
+						//need to seek before the next one.
+						return nil, false, nil
+					}
 				}
 			}
+			rec[name] = val
 		} else {
 			if _, errs := r.r.Seek(offset, io.SeekStart); errs != nil {
-				return nil, errs
+				return nil, false, errs
 			}
 		}
 	}
-	return rec, nil
-}
 
-func filterValue(filteredField FilterField, buf []byte) (filtered bool, err error) {
-	fieldValue, err := getFieldValueCasting(filteredField.Field, buf)
-	if err != nil {
-		return false, err
+	if r.condition != nil && !pureAnd {
+		ok, err := r.condition.eval(rec)
+		if err != nil {
+			return nil, true, err
+		}
+		if !ok {
+			return nil, true, nil
+		}
 	}
-	switch fieldValue.(type) {
-		case string:
-			switch filteredField.Filter.Condition {
-				case "=":
-					return fieldValue == filteredField.Filter.Value, nil
-				default:
-					return false,
-					fmt.Errorf("wrong filter condition operation (%s) for field %s (%s)",
-						filteredField.Filter.Condition,
-						filteredField.Field.Name,
-						"string")
-			}
-		case time.Time:
-			fieldValueTime := fieldValue.(time.Time)
-			filterValue, err := time.Parse("2006-01-02", filteredField.Filter.Value)
-			if err != nil {
-				return false,
-					fmt.Errorf(
-						"wrong filter type for field %s (format needed %s)",
-						filteredField.Field.Name,
-						"Y-m-d")
-			}
-			switch filteredField.Filter.Condition {
-				case "=":
-					return fieldValueTime.Equal(filterValue), nil
-				case ">":
-					return fieldValueTime.After(filterValue), nil
-				case "<":
-					return fieldValueTime.Before(filterValue), nil
-				default:
-					return false,
-						fmt.Errorf("wrong filter condition operation (%s) for field %s (%s)",
-							filteredField.Filter.Condition,
-							filteredField.Field.Name,
-							"time.Time")
-			}
-		case int:
-			fieldValueInt := fieldValue.(int)
-			filterValue, err := strconv.Atoi(filteredField.Filter.Value)
-			if err != nil {
-				return false,
-					fmt.Errorf("wrong filter type for field %s (format needed %s)",
-						filteredField.Field.Name,
-						"int")
-			}
-			switch filteredField.Filter.Condition {
-				case "=":
-					return fieldValueInt == filterValue, nil
-				case ">":
-					return fieldValueInt > filterValue, nil
-				case "<":
-					return fieldValueInt < filterValue, nil
-				default:
-					return false,
-						fmt.Errorf("wrong filter condition operation (%s) for field %s (%s)",
-							filteredField.Filter.Condition,
-							filteredField.Field.Name,
-							"int")
-			}
-		default:
-			return false, fmt.Errorf("unsupported filter type for field %s", filteredField.Field.Name)
+
+	//fields decoded only because the condition referenced them - not
+	//because they're part of the caller's projection - must not leak
+	//into the returned record.
+	for i, field := range r.fields {
+		if !field.Read {
+			delete(rec, r.FieldName(i))
+		}
 	}
+	return rec, true, nil
 }
 
-func getFieldValueCasting(f Field, buf []byte) (fieldCasting interface{}, err error) {
+//getFieldValueCasting decodes a single field's raw bytes into a Go value.
+//It is a method (rather than a free function) because 'M' and 'G' fields
+//store only a block pointer in the record - the actual value has to be
+//fetched from the Reader's sibling memo file.
+func (r *Reader) getFieldValueCasting(f Field, buf []byte) (fieldCasting interface{}, err error) {
 	fieldVal := strings.TrimSpace(string(buf))
 	switch f.Type {
 		case 'F': //Float
@@ -432,8 +437,18 @@ func getFieldValueCasting(f Field, buf []byte) (fieldCasting interface{}, err er
 			} else {
 				return time.Parse("20060102", fieldVal)
 			}
+		case 'T': //DateTime (Visual FoxPro) - Julian day + milliseconds since midnight
+			return decodeDateTime(buf)
+		case 'Y': //Currency (Visual FoxPro) - 8 byte integer scaled by 10000
+			return decodeCurrency(buf)
+		case 'B': //Double (Visual FoxPro) - 8 byte IEEE 754, little endian
+			return decodeDouble(buf)
+		case 'V': //Varchar (Visual FoxPro) - same on-disk shape as C, just variable length
+			return r.decodeFieldText(buf)
+		case 'M', 'G': //Memo / General (OLE) - buf holds a block pointer into the memo file
+			return r.readMemo(fieldVal)
 		default: //String value (C, padded with blanks) -Notice: blanks removed by the trim above
-			return fieldVal, err
+			return r.decodeFieldText(buf)
 	}
 }
 
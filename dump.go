@@ -0,0 +1,277 @@
+package dbf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//SQLDumpOptions controls the output of DumpSQL.
+type SQLDumpOptions struct {
+	BatchSize       int  // rows per multi-row INSERT statement; <= 0 defaults to 100
+	SkipCreateTable bool // when true, only the INSERT statements are written
+}
+
+//DumpSQL writes a CREATE TABLE statement derived from the DBF field
+//descriptors, followed by batched INSERT INTO statements covering every
+//record, in the style of Cockroach's dumpTable - a one-shot migration path
+//from a DBF file into any SQL database.
+func (r *Reader) DumpSQL(w io.Writer, table string, opts SQLDumpOptions) error {
+	if !opts.SkipCreateTable {
+		if err := writeCreateTable(w, table, r); err != nil {
+			return err
+		}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	cols := r.FieldNames()
+	r.SetReadFields(cols)
+	colTypes := r.columnTypes(cols)
+
+	quotedTable := quoteIdent(table)
+	quotedCols := make([]string, len(cols))
+	for i, c := range cols {
+		quotedCols[i] = quoteIdent(c)
+	}
+
+	var tuples []string
+	flush := func() error {
+		if len(tuples) == 0 {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES %s;\n",
+			quotedTable, strings.Join(quotedCols, ", "), strings.Join(tuples, ", "))
+		tuples = tuples[:0]
+		return err
+	}
+
+	cur := r.Iterator()
+	for cur.Next() {
+		rec := cur.Record()
+		vals := make([]string, len(cols))
+		for i, c := range cols {
+			vals[i] = sqlLiteral(rec[c], colTypes[i])
+		}
+		tuples = append(tuples, "("+strings.Join(vals, ", ")+")")
+		if len(tuples) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+//DumpCSV writes the whole table as CSV, with a header row of field names.
+func (r *Reader) DumpCSV(w io.Writer) error {
+	cols := r.FieldNames()
+	r.SetReadFields(cols)
+	colTypes := r.columnTypes(cols)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(cols); err != nil {
+		return err
+	}
+
+	cur := r.Iterator()
+	for cur.Next() {
+		rec := cur.Record()
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = csvValue(rec[c], colTypes[i])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//DumpJSON writes the whole table as a JSON array of one object per record,
+//streaming record-by-record rather than building the array in memory.
+func (r *Reader) DumpJSON(w io.Writer) error {
+	cols := r.FieldNames()
+	r.SetReadFields(cols)
+	colTypes := r.columnTypes(cols)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	cur := r.Iterator()
+	first := true
+	for cur.Next() {
+		rec := cur.Record()
+		obj := make(map[string]interface{}, len(cols))
+		for i, c := range cols {
+			obj[c] = jsonValue(rec[c], colTypes[i])
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+//writeCreateTable builds the column list from r.FieldName, the same
+//codepage-aware path DumpSQL uses for the INSERT column list - otherwise a
+//table with non-ASCII field names could get mismatched CREATE TABLE and
+//INSERT INTO column lists.
+func writeCreateTable(w io.Writer, table string, r *Reader) error {
+	defs := make([]string, len(r.fields))
+	for i, ff := range r.fields {
+		defs[i] = fmt.Sprintf("%s %s", quoteIdent(r.FieldName(i)), sqlColumnType(ff.Field))
+	}
+	_, err := fmt.Fprintf(w, "CREATE TABLE %s (\n  %s\n);\n", quoteIdent(table), strings.Join(defs, ",\n  "))
+	return err
+}
+
+//sqlColumnType maps a DBF field type to a SQL column type: C->VARCHAR(len),
+//N->NUMERIC(len,dec), I->INT, F->DOUBLE, D->DATE, L->BOOLEAN.
+func sqlColumnType(f Field) string {
+	switch f.Type {
+	case 'C':
+		return fmt.Sprintf("VARCHAR(%d)", f.Len)
+	case 'N':
+		return fmt.Sprintf("NUMERIC(%d,%d)", f.Len, f.DecimalPlaces)
+	case 'I':
+		return "INT"
+	case 'F':
+		return "DOUBLE"
+	case 'D':
+		return "DATE"
+	case 'L':
+		return "BOOLEAN"
+	default:
+		return fmt.Sprintf("VARCHAR(%d)", f.Len)
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+//columnTypes returns the DBF field type byte for each name in cols, in
+//order, so callers can tell a 'L' logical field's rune ('T'/'F'/' ') apart
+//from an 'I' integer field's int32 - both decode through the identical
+//int32 Go type, so that distinction can't be made from the value alone.
+func (r *Reader) columnTypes(cols []string) []byte {
+	types := make([]byte, len(cols))
+	for i, c := range cols {
+		for j, ff := range r.fields {
+			if r.FieldName(j) == c {
+				types[i] = ff.Field.Type
+				break
+			}
+		}
+	}
+	return types
+}
+
+//sqlLiteral renders a decoded field value as a SQL literal, quoting
+//strings and dates and emitting NULL for empty dates and unset booleans.
+func sqlLiteral(v interface{}, fieldType byte) string {
+	if v == nil {
+		return "NULL"
+	}
+	if fieldType == 'L' {
+		switch v.(int32) {
+		case 'T':
+			return "TRUE"
+		case 'F':
+			return "FALSE"
+		default: // unset logical field
+			return "NULL"
+		}
+	}
+	switch val := v.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+	case int:
+		return strconv.Itoa(val)
+	case int32:
+		return strconv.FormatInt(int64(val), 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return "'" + val.Format("2006-01-02") + "'"
+	default:
+		return "'" + fmt.Sprint(val) + "'"
+	}
+}
+
+func csvValue(v interface{}, fieldType byte) string {
+	if v == nil {
+		return ""
+	}
+	if fieldType == 'L' {
+		switch v.(int32) {
+		case 'T':
+			return "T"
+		case 'F':
+			return "F"
+		default:
+			return ""
+		}
+	}
+	switch val := v.(type) {
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case int32:
+		return strconv.FormatInt(int64(val), 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return val.Format("2006-01-02")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func jsonValue(v interface{}, fieldType byte) interface{} {
+	if v == nil || fieldType != 'L' {
+		if n, ok := v.(int32); ok {
+			return int64(n)
+		}
+		return v
+	}
+	switch v.(int32) {
+	case 'T':
+		return true
+	case 'F':
+		return false
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustOpenDumpTable(t *testing.T) *Reader {
+	t.Helper()
+	path := t.TempDir() + "/dump.dbf"
+	fields := []Field{
+		{Name: nameBytes("NAME"), Type: 'C', Len: 10},
+		{Name: nameBytes("AGE"), Type: 'N', Len: 5, DecimalPlaces: 0},
+		{Name: nameBytes("ACTIVE"), Type: 'L', Len: 1},
+	}
+	mustWriteTestTable(t, path, fields, []Record{
+		{"NAME": "alice", "AGE": 20, "ACTIVE": true},
+		{"NAME": "bob", "AGE": 30, "ACTIVE": false},
+	})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestDumpSQL(t *testing.T) {
+	r := mustOpenDumpTable(t)
+
+	var buf bytes.Buffer
+	if err := r.DumpSQL(&buf, "people", SQLDumpOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	wantCreate := "CREATE TABLE \"people\" (\n" +
+		"  \"NAME\" VARCHAR(10),\n" +
+		"  \"AGE\" NUMERIC(5,0),\n" +
+		"  \"ACTIVE\" BOOLEAN\n" +
+		");\n"
+	if !strings.Contains(out, wantCreate) {
+		t.Errorf("CREATE TABLE mismatch, got:\n%s", out)
+	}
+
+	wantInsert := `INSERT INTO "people" ("NAME", "AGE", "ACTIVE") VALUES ('alice', 20, TRUE), ('bob', 30, FALSE);`
+	if !strings.Contains(out, wantInsert) {
+		t.Errorf("INSERT mismatch, got:\n%s", out)
+	}
+}
+
+func TestDumpCSV(t *testing.T) {
+	r := mustOpenDumpTable(t)
+
+	var buf bytes.Buffer
+	if err := r.DumpCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME,AGE,ACTIVE\nalice,20,T\nbob,30,F\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	r := mustOpenDumpTable(t)
+
+	var buf bytes.Buffer
+	if err := r.DumpJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]interface{}{
+		{"NAME": "alice", "AGE": float64(20), "ACTIVE": true},
+		{"NAME": "bob", "AGE": float64(30), "ACTIVE": false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
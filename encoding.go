@@ -0,0 +1,69 @@
+package dbf
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+//languageDriverEncodings maps the DBF header's language driver byte (offset
+//0x1D) to the encoding.Encoding used to decode 'C'/memo field text and
+//field names. Not exhaustive - just the codepages this package has actually
+//been asked to read in the wild.
+var languageDriverEncodings = map[byte]encoding.Encoding{
+	0x01: charmap.CodePage437,         // U.S. MS-DOS
+	0x02: charmap.CodePage850,         // International MS-DOS
+	0x03: charmap.Windows1252,         // Windows ANSI
+	0x57: charmap.Windows1252,         // ANSI
+	0x65: charmap.CodePage866,         // Russian MS-DOS
+	0x66: charmap.CodePage852,         // Eastern European MS-DOS
+	0x4D: simplifiedchinese.GBK,       // Chinese GBK (936)
+	0x7A: traditionalchinese.Big5,     // Traditional Chinese (950)
+	0x7C: korean.EUCKR,                // Korean (949)
+	0x7B: japanese.ShiftJIS,           // Japanese Shift-JIS (932)
+	0xC8: charmap.Windows1250,         // Eastern European Windows
+	0xC9: charmap.Windows1251,         // Russian Windows
+	0xCA: charmap.Windows1253,         // Greek Windows
+	0xCB: charmap.Windows1254,         // Turkish Windows
+}
+
+//encodingForLanguageDriver returns the encoding.Encoding matching b, or nil
+//if b is unrecognized - the caller then treats field text as raw bytes,
+//same as before this package understood codepages.
+func encodingForLanguageDriver(b byte) encoding.Encoding {
+	return languageDriverEncodings[b]
+}
+
+//decodeBytes transforms buf from the table's codepage into UTF-8. If no
+//encoding is set (unrecognized or absent language driver byte, and
+//SetEncoding was never called), buf is returned unchanged.
+func (r *Reader) decodeBytes(buf []byte) (string, error) {
+	if r.enc == nil {
+		return string(buf), nil
+	}
+	tr := transform.NewReader(bytes.NewReader(buf), r.enc.NewDecoder())
+	out, err := io.ReadAll(tr)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+//decodeFieldText decodes a 'C'/'V' field's raw bytes using the table's
+//codepage and trims leading and trailing whitespace, including the space
+//padding.
+func (r *Reader) decodeFieldText(buf []byte) (string, error) {
+	s, err := r.decodeBytes(buf)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
@@ -0,0 +1,50 @@
+package dbf
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestEncodingForLanguageDriver(t *testing.T) {
+	if enc := encodingForLanguageDriver(0x01); enc != charmap.CodePage437 {
+		t.Errorf("0x01: got %v, want CodePage437", enc)
+	}
+	if enc := encodingForLanguageDriver(0xFF); enc != nil {
+		t.Errorf("0xFF: got %v, want nil for an unrecognized language driver byte", enc)
+	}
+}
+
+func TestDecodeBytesPassthroughWithoutEncoding(t *testing.T) {
+	r := &Reader{}
+	got, err := r.decodeBytes([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "hello" {
+		t.Errorf("got %q, want %q", got, "hello")
+	}
+}
+
+func TestDecodeBytesAppliesCodepage(t *testing.T) {
+	r := &Reader{enc: charmap.CodePage437}
+	// 0x85 is 'à' in CP437, not valid UTF-8 on its own.
+	got, err := r.decodeBytes([]byte{0x85})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "à" {
+		t.Errorf("got %q, want %q", got, "à")
+	}
+}
+
+func TestSetEncodingOverride(t *testing.T) {
+	r := &Reader{}
+	if r.Encoding() != nil {
+		t.Fatal("expected a nil default encoding")
+	}
+	r.SetEncoding(charmap.Windows1252)
+	if r.Encoding() != charmap.Windows1252 {
+		t.Fatal("SetEncoding did not take effect")
+	}
+}
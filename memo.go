@@ -0,0 +1,216 @@
+package dbf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//versionCaps describes what a given DBF version byte is allowed to carry.
+type versionCaps struct {
+	memo    bool   // table may reference a memo (.dbt/.fpt) file
+	memoExt string // extension of the sibling memo file, including the dot
+}
+
+//supportedVersions lists the DBF version bytes this package understands,
+//along with their per-version capabilities. NewReader rejects any version
+//not present here.
+var supportedVersions = map[byte]versionCaps{
+	0x03: {memo: false},                 // dBase III, no memo
+	0x83: {memo: true, memoExt: ".dbt"}, // dBase III+ with memo
+	0x30: {memo: true, memoExt: ".fpt"}, // Visual FoxPro
+	0x31: {memo: true, memoExt: ".fpt"}, // Visual FoxPro with AutoIncrement
+	0xF5: {memo: true, memoExt: ".fpt"}, // FoxPro 2.x with memo
+}
+
+//memoFile wraps an open .dbt (dBase III) or .fpt (FoxPro) memo file.
+type memoFile struct {
+	r         io.ReadSeeker
+	blockSize uint32
+	foxPro    bool // true for .fpt block-header format, false for .dbt 0x1A 0x1A terminated text
+}
+
+//needsMemo reports whether any field in the table requires a memo file.
+func (r *Reader) needsMemo() bool {
+	for _, ff := range r.fields {
+		if ff.Field.Type == 'M' || ff.Field.Type == 'G' {
+			return true
+		}
+	}
+	return false
+}
+
+//NewReaderFromPath opens the DBF file at path and, if its fields include a
+//memo ('M') or general ('G') field, also opens the sibling memo file
+//(.dbt for dBase III, .fpt for Visual FoxPro/FoxPro 2.x) so that those
+//fields can be resolved when records are read.
+func NewReaderFromPath(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if r.needsMemo() {
+		if err := r.openMemo(path); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+//openMemo locates and opens the memo file sibling to the DBF file at path,
+//using the extension appropriate to the table's version.
+func (r *Reader) openMemo(path string) error {
+	caps, ok := supportedVersions[r.fileVersion]
+	if !ok || !caps.memo {
+		return fmt.Errorf("dbf: file has memo fields but version %#x does not support a memo file", r.fileVersion)
+	}
+	ext := caps.memoExt
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	mf, err := os.Open(base + ext)
+	if err != nil {
+		return fmt.Errorf("dbf: could not open memo file for %s: %w", path, err)
+	}
+	memo, err := newMemoFile(mf, caps.memoExt == ".fpt")
+	if err != nil {
+		mf.Close()
+		return err
+	}
+	r.memo = memo
+	return nil
+}
+
+//newMemoFile reads a memo file's header and returns a memoFile ready for
+//block lookups. dBase III .dbt files use a fixed 512 byte block size;
+//Visual FoxPro .fpt files store it at header offset 6 (big endian uint16).
+func newMemoFile(r io.ReadSeeker, foxPro bool) (*memoFile, error) {
+	blockSize := uint32(512)
+	if foxPro {
+		if _, err := r.Seek(6, io.SeekStart); err != nil {
+			return nil, err
+		}
+		var bs uint16
+		if err := binary.Read(r, binary.BigEndian, &bs); err != nil {
+			return nil, err
+		}
+		if bs != 0 {
+			blockSize = uint32(bs)
+		}
+	}
+	return &memoFile{r: r, blockSize: blockSize, foxPro: foxPro}, nil
+}
+
+//readAt returns the raw bytes stored in memo block index, stripped of the
+//format's own framing (the FoxPro block header, or the dBase III 0x1A 0x1A
+//terminator and NUL padding) - codepage decoding is left to the caller.
+func (m *memoFile) readAt(index uint32) ([]byte, error) {
+	if _, err := m.r.Seek(int64(index)*int64(m.blockSize), io.SeekStart); err != nil {
+		return nil, err
+	}
+	if m.foxPro {
+		var blockType, length uint32
+		if err := binary.Read(m.r, binary.BigEndian, &blockType); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(m.r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(m.r, buf); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	buf := make([]byte, m.blockSize)
+	n, err := io.ReadFull(m.r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	buf = buf[:n]
+	if end := strings.Index(string(buf), "\x1a\x1a"); end >= 0 {
+		buf = buf[:end]
+	}
+	return bytes.TrimRight(buf, "\x00"), nil
+}
+
+//readMemo resolves the block pointer stored (as ASCII digits) in a memo or
+//general field's raw bytes to the text held in the sibling memo file. An
+//empty or all-blank field has no memo block and decodes to "".
+func (r *Reader) readMemo(blockPointer string) (string, error) {
+	if blockPointer == "" {
+		return "", nil
+	}
+	if r.memo == nil {
+		return "", fmt.Errorf("dbf: record references a memo field but no memo file is open (use NewReaderFromPath)")
+	}
+	index, err := strconv.ParseUint(blockPointer, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("dbf: invalid memo block pointer %q: %w", blockPointer, err)
+	}
+	buf, err := r.memo.readAt(uint32(index))
+	if err != nil {
+		return "", err
+	}
+	return r.decodeBytes(buf)
+}
+
+//decodeDateTime decodes a 'T' field: 4 bytes of Julian day number followed
+//by 4 bytes of milliseconds since midnight, both little endian.
+func decodeDateTime(buf []byte) (time.Time, error) {
+	if len(buf) != 8 {
+		return time.Time{}, fmt.Errorf("dbf: datetime field must be 8 bytes, got %d", len(buf))
+	}
+	julian := int32(binary.LittleEndian.Uint32(buf[0:4]))
+	msSinceMidnight := int32(binary.LittleEndian.Uint32(buf[4:8]))
+	if julian == 0 {
+		return time.Time{}, nil
+	}
+	year, month, day := julianDayToDate(julian)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).
+		Add(time.Duration(msSinceMidnight) * time.Millisecond), nil
+}
+
+//julianDayToDate converts a Julian day number to a proleptic Gregorian
+//calendar date, using the standard integer algorithm (Richards, 2013).
+func julianDayToDate(jd int32) (year, month, day int) {
+	a := int64(jd) + 32044
+	b := (4*a + 3) / 146097
+	c := a - (146097*b)/4
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+	day = int(e - (153*m+2)/5 + 1)
+	month = int(m + 3 - 12*(m/10))
+	year = int(100*b + d - 4800 + m/10)
+	return
+}
+
+//decodeCurrency decodes a 'Y' field: an 8 byte little endian integer
+//scaled by 10000 (i.e. 4 implied decimal places).
+func decodeCurrency(buf []byte) (float64, error) {
+	if len(buf) != 8 {
+		return 0, fmt.Errorf("dbf: currency field must be 8 bytes, got %d", len(buf))
+	}
+	return float64(int64(binary.LittleEndian.Uint64(buf))) / 10000, nil
+}
+
+//decodeDouble decodes a 'B' field: an 8 byte little endian IEEE 754 double.
+func decodeDouble(buf []byte) (float64, error) {
+	if len(buf) != 8 {
+		return 0, fmt.Errorf("dbf: double field must be 8 bytes, got %d", len(buf))
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf)), nil
+}
@@ -0,0 +1,26 @@
+package dbf
+
+import "testing"
+
+//TestDecodeCurrencyAndDoubleRejectShortBuffers is a regression test: a
+//malformed Y/B field whose declared Len isn't 8 must return an error
+//instead of panicking, matching decodeDateTime's existing length guard.
+func TestDecodeCurrencyAndDoubleRejectShortBuffers(t *testing.T) {
+	if _, err := decodeCurrency([]byte{1, 2, 3, 4}); err == nil {
+		t.Error("decodeCurrency: expected an error for a 4 byte buffer, got nil")
+	}
+	if _, err := decodeDouble([]byte{1, 2, 3, 4}); err == nil {
+		t.Error("decodeDouble: expected an error for a 4 byte buffer, got nil")
+	}
+
+	if _, err := getFieldValueCastingStandalone(Field{Type: 'Y', Len: 4}, []byte{1, 2, 3, 4}); err == nil {
+		t.Error("getFieldValueCasting: expected an error for a malformed 'Y' field, got nil")
+	}
+}
+
+//getFieldValueCastingStandalone exercises getFieldValueCasting without a
+//full Reader - (*Reader)(nil) is safe here since 'Y' never touches r.
+func getFieldValueCastingStandalone(f Field, buf []byte) (interface{}, error) {
+	var r *Reader
+	return r.getFieldValueCasting(f, buf)
+}
@@ -0,0 +1,543 @@
+// Package sql implements a database/sql/driver wrapper around dbf.Reader,
+// so a DBF table can be opened with sql.Open("dbf", path) and queried with
+// a small subset of SQL: projection, WHERE (AND/OR of comparisons), ORDER BY
+// and LIMIT/OFFSET.
+//
+//	db, _ := sql.Open("dbf", "table.dbf")
+//	rows, _ := db.Query("SELECT col1, col2 FROM t WHERE col3 = 'x' ORDER BY col1 LIMIT 100")
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/x5hell/dbf"
+)
+
+func init() {
+	sql.Register("dbf", &Driver{})
+}
+
+//Driver is the database/sql/driver.Driver implementation for DBF tables.
+type Driver struct{}
+
+//Open opens the DBF file at name and returns a driver.Conn wrapping it.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := dbf.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.SetFlags(dbf.FlagSkipDeleted)
+	return &conn{f: f, r: r}, nil
+}
+
+//conn wraps a single open DBF file. A DBF file has no notion of multiple
+//tables, so the table name in "FROM table" is accepted but not checked.
+type conn struct {
+	f *os.File
+	r *dbf.Reader
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, query: q}, nil
+}
+
+func (c *conn) Close() error {
+	return c.f.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("dbf: transactions are not supported")
+}
+
+//stmt is a prepared (parsed) SELECT statement. It carries no bind
+//parameters, since the SQL subset supported here has none.
+type stmt struct {
+	conn  *conn
+	query *query
+}
+
+func (s *stmt) Close() error { return nil }
+
+func (s *stmt) NumInput() int { return 0 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("dbf: only SELECT statements are supported")
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	r := s.conn.r
+	allFields := r.FieldNames()
+
+	projected := s.query.columns
+	if len(projected) == 0 || (len(projected) == 1 && projected[0] == "*") {
+		projected = allFields
+	}
+
+	fieldIndex := make(map[string]int, len(allFields))
+	for i, name := range allFields {
+		fieldIndex[name] = i
+	}
+	annotateFieldTypes(s.query.where, r, fieldIndex)
+
+	pushed, complete, err := buildCondition(s.query.where, r, fieldIndex)
+	if err != nil {
+		return nil, err
+	}
+	r.SetFilter(pushed)
+
+	var residual *whereExpr
+	if !complete {
+		residual = s.query.where
+	}
+
+	readFields := unionFields(projected, residual, s.query.orderBy)
+	r.SetReadFields(readFields)
+
+	var matched []dbf.Record
+	cur := r.Iterator()
+	for cur.Next() {
+		rec := cur.Record()
+		ok, err := evalWhere(residual, rec)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, rec)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(s.query.orderBy) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return lessRecords(matched[i], matched[j], s.query.orderBy)
+		})
+	}
+
+	matched = applyLimitOffset(matched, s.query.offset, s.query.limit)
+
+	colTypes := make([]byte, len(projected))
+	for i, c := range projected {
+		if fi, err := r.FieldInfo(fieldIndex[c]); err == nil {
+			colTypes[i] = fi.Type
+		}
+	}
+
+	return &rows{columns: projected, columnTypes: colTypes, records: matched}, nil
+}
+
+//annotateFieldTypes walks a parsed WHERE tree and records each leaf's DBF
+//field type, so evalCond can later tell an 'L' logical field's rune value
+//('T'/'F'/' ') apart from an 'I' field's int32 - both decode to the same Go
+//type, so the distinction can't be made from the decoded value alone.
+func annotateFieldTypes(e *whereExpr, r *dbf.Reader, fieldIndex map[string]int) {
+	if e == nil {
+		return
+	}
+	if e.op == "and" || e.op == "or" {
+		annotateFieldTypes(e.left, r, fieldIndex)
+		annotateFieldTypes(e.right, r, fieldIndex)
+		return
+	}
+	if idx, ok := fieldIndex[e.cond.field]; ok {
+		if fi, err := r.FieldInfo(idx); err == nil {
+			e.cond.fieldType = fi.Type
+		}
+	}
+}
+
+//unionFields computes the columns Reader.SetReadFields needs to decode:
+//the projection, ORDER BY columns, and anything left in the residual
+//(non-pushed-down) WHERE clause. Columns referenced only by the pushed
+//dbf.Condition are deliberately NOT included here - Reader decodes those
+//itself, regardless of SetReadFields, because they're part of the
+//condition it was given via SetFilter (see dbf.Reader.readRecord).
+func unionFields(projected []string, residual *whereExpr, orderBy []orderSpec) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	for _, f := range projected {
+		add(f)
+	}
+	for _, f := range residualFields(residual) {
+		add(f)
+	}
+	for _, o := range orderBy {
+		add(o.field)
+	}
+	return out
+}
+
+func residualFields(e *whereExpr) []string {
+	if e == nil {
+		return nil
+	}
+	if e.op == "and" || e.op == "or" {
+		return append(residualFields(e.left), residualFields(e.right)...)
+	}
+	return []string{e.cond.field}
+}
+
+func applyLimitOffset(recs []dbf.Record, offset, limit int64) []dbf.Record {
+	if offset > 0 {
+		if offset >= int64(len(recs)) {
+			return nil
+		}
+		recs = recs[offset:]
+	}
+	if limit >= 0 && limit < int64(len(recs)) {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+//rows is the driver.Rows implementation returned by Query.
+type rows struct {
+	columns     []string
+	columnTypes []byte // DBF field type per column; see annotateFieldTypes
+	records     []dbf.Record
+	pos         int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.records) {
+		return io.EOF
+	}
+	rec := r.records[r.pos]
+	r.pos++
+	for i, col := range r.columns {
+		v, err := toDriverValue(rec[col], r.columnTypes[i])
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+//toDriverValue converts a dbf.Record value into one of the types
+//driver.Value allows: int64, float64, bool, []byte, string, time.Time, nil.
+func toDriverValue(v interface{}, fieldType byte) (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if fieldType == 'L' {
+		switch v.(int32) {
+		case 'T':
+			return true, nil
+		case 'F':
+			return false, nil
+		default: // ' ' - logical field left unset
+			return nil, nil
+		}
+	}
+	switch val := v.(type) {
+	case int:
+		return int64(val), nil
+	case int32:
+		return int64(val), nil
+	case float64, string, time.Time:
+		return val, nil
+	default:
+		return nil, fmt.Errorf("dbf: unsupported field value type %T", v)
+	}
+}
+
+func lessRecords(a, b dbf.Record, orderBy []orderSpec) bool {
+	for _, o := range orderBy {
+		c := compareValues(a[o.field], b[o.field])
+		if c == 0 {
+			continue
+		}
+		if o.desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+//compareValues returns -1, 0 or 1, comparing same-typed field values.
+func compareValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case string:
+		bv, _ := b.(string)
+		return strcmp(av, bv)
+	case int:
+		bv, _ := b.(int)
+		return intcmp(av, bv)
+	case int32:
+		bv, _ := b.(int32)
+		return intcmp(int(av), int(bv))
+	case float64:
+		bv, _ := b.(float64)
+		return floatcmp(av, bv)
+	case time.Time:
+		bv, _ := b.(time.Time)
+		switch {
+		case av.Before(bv):
+			return -1
+		case av.After(bv):
+			return 1
+		default:
+			return 0
+		}
+	default:
+		return 0
+	}
+}
+
+func strcmp(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func intcmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func floatcmp(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+//buildCondition tries to translate the whole parsed WHERE tree into a
+//dbf.Condition so it can be pushed into Reader.SetFilter and evaluated by
+//the library itself instead of record-by-record in Go. It succeeds (ok
+//true) whenever the tree is in the disjunctive-normal-form shape that
+//dbf.Condition can express - an OR of AND-groups of plain comparisons. Any
+//other shape (e.g. parenthesised OR nested inside an AND) falls back to
+//full residual evaluation in evalWhere, same as before Condition existed.
+func buildCondition(where *whereExpr, r *dbf.Reader, fieldIndex map[string]int) (*dbf.Condition, bool, error) {
+	if where == nil {
+		return nil, true, nil
+	}
+	var cond *dbf.Condition
+	for i, term := range flattenOr(where) {
+		leaves, pure := flattenAndLeaves(term)
+		if !pure {
+			return nil, false, nil
+		}
+		group := dbf.NewCondition()
+		for _, leaf := range leaves {
+			arg, err := typedValue(leaf.cond.field, leaf.cond.value, r, fieldIndex)
+			if err != nil {
+				return nil, false, err
+			}
+			group = group.And(leaf.cond.field, dbf.Operator(leaf.cond.op), arg)
+		}
+		if i == 0 {
+			cond = group
+		} else {
+			cond = cond.Or(group)
+		}
+	}
+	return cond, true, nil
+}
+
+func flattenOr(e *whereExpr) []*whereExpr {
+	if e == nil {
+		return nil
+	}
+	if e.op == "or" {
+		return append(flattenOr(e.left), flattenOr(e.right)...)
+	}
+	return []*whereExpr{e}
+}
+
+//flattenAndLeaves flattens a chain of ANDed comparisons into leaves. It
+//returns ok=false if it finds a nested OR, which buildCondition cannot
+//express as a single AND-group.
+func flattenAndLeaves(e *whereExpr) (leaves []*whereExpr, ok bool) {
+	if e == nil {
+		return nil, true
+	}
+	if e.op == "or" {
+		return nil, false
+	}
+	if e.op == "and" {
+		l, lok := flattenAndLeaves(e.left)
+		rgt, rok := flattenAndLeaves(e.right)
+		return append(l, rgt...), lok && rok
+	}
+	return []*whereExpr{e}, true
+}
+
+//typedValue converts a literal token from the SQL text into the Go type
+//that matches the target field, mirroring the inverse of getFieldValueCasting.
+func typedValue(field, literal string, r *dbf.Reader, fieldIndex map[string]int) (interface{}, error) {
+	idx, ok := fieldIndex[field]
+	if !ok {
+		return nil, fmt.Errorf("dbf: unknown column %q", field)
+	}
+	fi, err := r.FieldInfo(idx)
+	if err != nil {
+		return nil, err
+	}
+	switch fi.Type {
+	case 'N':
+		if fi.DecimalPlaces == 0 {
+			return strconv.Atoi(literal)
+		}
+		return strconv.ParseFloat(literal, 64)
+	case 'F':
+		return strconv.ParseFloat(literal, 64)
+	case 'I':
+		return strconv.Atoi(literal)
+	case 'L': // decoded as the rune 'T'/'F'/' '
+		if literal == "true" || literal == "T" || literal == "1" {
+			return int32('T'), nil
+		}
+		return int32('F'), nil
+	case 'D':
+		return time.Parse("2006-01-02", literal)
+	default: // 'C' and anything else decodes as a string
+		return literal, nil
+	}
+}
+
+//evalWhere evaluates the residual (non-pushed-down) part of a WHERE clause
+//against a decoded record.
+func evalWhere(e *whereExpr, rec dbf.Record) (bool, error) {
+	if e == nil {
+		return true, nil
+	}
+	switch e.op {
+	case "and":
+		left, err := evalWhere(e.left, rec)
+		if err != nil || !left {
+			return false, err
+		}
+		return evalWhere(e.right, rec)
+	case "or":
+		left, err := evalWhere(e.left, rec)
+		if err != nil {
+			return false, err
+		}
+		if left {
+			return true, nil
+		}
+		return evalWhere(e.right, rec)
+	default:
+		return evalCond(e.cond, rec[e.cond.field])
+	}
+}
+
+func evalCond(c cond, fieldValue interface{}) (bool, error) {
+	if fieldValue == nil {
+		return false, nil
+	}
+	if c.fieldType == 'L' {
+		want := c.value == "T" || c.value == "true" || c.value == "1"
+		got := fieldValue.(int32) == 'T'
+		switch c.op {
+		case "=":
+			return got == want, nil
+		case "!=":
+			return got != want, nil
+		default:
+			return false, fmt.Errorf("dbf: operator %q not supported for logical field %s", c.op, c.field)
+		}
+	}
+	switch v := fieldValue.(type) {
+	case string:
+		return compareOp(c.op, strcmp(v, c.value))
+	case int:
+		n, err := strconv.Atoi(c.value)
+		if err != nil {
+			return false, fmt.Errorf("dbf: %q is not a valid int for field %s", c.value, c.field)
+		}
+		return compareOp(c.op, intcmp(v, n))
+	case int32:
+		n, err := strconv.ParseInt(c.value, 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("dbf: %q is not a valid int for field %s", c.value, c.field)
+		}
+		return compareOp(c.op, intcmp(int(v), int(n)))
+	case float64:
+		f, err := strconv.ParseFloat(c.value, 64)
+		if err != nil {
+			return false, fmt.Errorf("dbf: %q is not a valid float for field %s", c.value, c.field)
+		}
+		return compareOp(c.op, floatcmp(v, f))
+	case time.Time:
+		t, err := time.Parse("2006-01-02", c.value)
+		if err != nil {
+			return false, fmt.Errorf("dbf: %q is not a valid date (YYYY-MM-DD) for field %s", c.value, c.field)
+		}
+		switch {
+		case v.Before(t):
+			return compareOp(c.op, -1)
+		case v.After(t):
+			return compareOp(c.op, 1)
+		default:
+			return compareOp(c.op, 0)
+		}
+	default:
+		return false, fmt.Errorf("dbf: unsupported field value type %T for field %s", fieldValue, c.field)
+	}
+}
+
+func compareOp(op string, c int) (bool, error) {
+	switch op {
+	case "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	default:
+		return false, fmt.Errorf("dbf: unsupported operator %q", op)
+	}
+}
@@ -0,0 +1,77 @@
+package sql
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/x5hell/dbf"
+)
+
+func mustBuildTable(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	fields := []dbf.Field{
+		{Type: 'C', Len: 10},
+		{Type: 'N', Len: 5, DecimalPlaces: 0},
+	}
+	copy(fields[0].Name[:], "NAME")
+	copy(fields[1].Name[:], "AGE")
+
+	w, err := dbf.NewWriter(f, fields, 0x03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []dbf.Record{
+		{"NAME": "alice", "AGE": 20},
+		{"NAME": "bob", "AGE": 30},
+	} {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//TestQueryOrPushdownOverNonProjectedColumn is a regression test: a WHERE
+//clause OR-ing comparisons on a column outside the SELECT list must still
+//be evaluated against that column instead of being silently dropped - see
+//buildCondition/unionFields.
+func TestQueryOrPushdownOverNonProjectedColumn(t *testing.T) {
+	path := t.TempDir() + "/people.dbf"
+	mustBuildTable(t, path)
+
+	db, err := sql.Open("dbf", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT NAME FROM t WHERE AGE > 1000 OR AGE = 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "bob" {
+		t.Fatalf("expected [bob], got %v", got)
+	}
+}
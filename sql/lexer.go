@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokKind identifies the lexical category of a token.
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokPunct
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+// lex splits a SQL statement into tokens, understanding quoted strings
+// ('literal') and the multi-char operators <=, >=, != and <>.
+func lex(sql string) ([]token, error) {
+	var toks []token
+	r := []rune(sql)
+	i, n := 0, len(r)
+	for i < n {
+		c := r[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			j := i + 1
+			var sb strings.Builder
+			for j < n && r[j] != '\'' {
+				sb.WriteRune(r[j])
+				j++
+			}
+			if j >= n {
+				return nil, fmt.Errorf("sql: unterminated string literal")
+			}
+			toks = append(toks, token{tokString, sb.String()})
+			i = j + 1
+		case c == ',' || c == '*' || c == '(' || c == ')':
+			toks = append(toks, token{tokPunct, string(c)})
+			i++
+		case c == '<' || c == '>' || c == '!' || c == '=':
+			op := string(c)
+			if i+1 < n && r[i+1] == '=' {
+				op += "="
+				i += 2
+			} else if c == '<' && i+1 < n && r[i+1] == '>' {
+				op = "!="
+				i += 2
+			} else {
+				i++
+			}
+			toks = append(toks, token{tokPunct, op})
+		case isIdentStart(c):
+			j := i
+			for j < n && isIdentPart(r[j]) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, string(r[i:j])})
+			i = j
+		case isDigit(c) || (c == '-' && i+1 < n && isDigit(r[i+1])):
+			j := i + 1
+			for j < n && (isDigit(r[j]) || r[j] == '.' || r[j] == '-') {
+				j++
+			}
+			toks = append(toks, token{tokNumber, string(r[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("sql: unexpected character %q", c)
+		}
+	}
+	toks = append(toks, token{tokEOF, ""})
+	return toks, nil
+}
+
+func isIdentStart(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c rune) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
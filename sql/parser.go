@@ -0,0 +1,259 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cond is a single field/operator/value comparison, e.g. "col3 = 'x'".
+// fieldType is filled in later, once a Reader is available, with the DBF
+// field type byte ('L', 'I', ...) so evalCond can disambiguate values whose
+// Go type alone is ambiguous (see annotateFieldTypes).
+type cond struct {
+	field     string
+	op        string
+	value     string
+	fieldType byte
+}
+
+// whereExpr is a boolean combination of conditions. A leaf node has
+// cond set and left/right nil; an "and"/"or" node has left and right
+// set and cond zeroed.
+type whereExpr struct {
+	op          string // "and", "or" or "" for a leaf
+	cond        cond
+	left, right *whereExpr
+}
+
+type orderSpec struct {
+	field string
+	desc  bool
+}
+
+// query is the parsed form of the small SELECT subset this package
+// understands: projection, WHERE with AND/OR of comparisons, ORDER BY
+// and LIMIT/OFFSET.
+type query struct {
+	table   string
+	columns []string // nil or {"*"} means "all fields"
+	where   *whereExpr
+	orderBy []orderSpec
+	limit   int64 // -1 means unset
+	offset  int64
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// parseQuery parses a single SELECT statement.
+func parseQuery(sqlText string) (*query, error) {
+	toks, err := lex(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+
+	if !p.consumeKeyword("select") {
+		return nil, fmt.Errorf("sql: only SELECT statements are supported")
+	}
+
+	q := &query{limit: -1, offset: -1}
+	q.columns, err = p.parseColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.consumeKeyword("from") {
+		return nil, fmt.Errorf("sql: expected FROM")
+	}
+	q.table, err = p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.consumeKeyword("where") {
+		q.where, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.consumeKeyword("order") {
+		if !p.consumeKeyword("by") {
+			return nil, fmt.Errorf("sql: expected BY after ORDER")
+		}
+		q.orderBy, err = p.parseOrderBy()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.consumeKeyword("limit") {
+		n, err := p.expectNumber()
+		if err != nil {
+			return nil, err
+		}
+		q.limit = n
+	}
+	if p.consumeKeyword("offset") {
+		n, err := p.expectNumber()
+		if err != nil {
+			return nil, err
+		}
+		q.offset = n
+	}
+
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("sql: unexpected token %q", p.peek().text)
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if p.pos < len(p.toks)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) consumeKeyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, kw) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectIdent() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("sql: expected identifier, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) expectNumber() (int64, error) {
+	t := p.next()
+	if t.kind != tokNumber {
+		return 0, fmt.Errorf("sql: expected number, got %q", t.text)
+	}
+	var n int64
+	_, err := fmt.Sscanf(t.text, "%d", &n)
+	return n, err
+}
+
+func (p *parser) parseColumns() ([]string, error) {
+	if p.peek().kind == tokPunct && p.peek().text == "*" {
+		p.next()
+		return []string{"*"}, nil
+	}
+	var cols []string
+	for {
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+		if p.peek().kind == tokPunct && p.peek().text == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	return cols, nil
+}
+
+func (p *parser) parseOrderBy() ([]orderSpec, error) {
+	var specs []orderSpec
+	for {
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		spec := orderSpec{field: name}
+		if p.consumeKeyword("desc") {
+			spec.desc = true
+		} else {
+			p.consumeKeyword("asc")
+		}
+		specs = append(specs, spec)
+		if p.peek().kind == tokPunct && p.peek().text == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	return specs, nil
+}
+
+// parseOr parses a sequence of parseAnd terms joined by OR.
+func (p *parser) parseOr() (*whereExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses a sequence of comparisons joined by AND.
+func (p *parser) parseAnd() (*whereExpr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (*whereExpr, error) {
+	if p.peek().kind == tokPunct && p.peek().text == "(" {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !(p.peek().kind == tokPunct && p.peek().text == ")") {
+			return nil, fmt.Errorf("sql: expected )")
+		}
+		p.next()
+		return e, nil
+	}
+
+	field, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.next()
+	if opTok.kind != tokPunct {
+		return nil, fmt.Errorf("sql: expected comparison operator, got %q", opTok.text)
+	}
+	switch opTok.text {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("sql: unsupported operator %q", opTok.text)
+	}
+	valTok := p.next()
+	if valTok.kind != tokString && valTok.kind != tokNumber {
+		return nil, fmt.Errorf("sql: expected value, got %q", valTok.text)
+	}
+	return &whereExpr{cond: cond{field: field, op: opTok.text, value: valTok.text}}, nil
+}
@@ -0,0 +1,434 @@
+package dbf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Writer appends records to a DBF file. Use NewWriter to start a brand-new
+//file, or OpenReadWrite to append to (and pack) one that already exists.
+type Writer struct {
+	w         io.WriteSeeker
+	fields    []Field
+	version   byte
+	headerlen uint16
+	recordlen uint16
+	nrec      uint32
+	sync.Mutex
+}
+
+//NewWriter creates a Writer for a brand-new DBF file, writing the header
+//and field descriptors immediately. fields must already have Name and Type
+//(and, for 'N', DecimalPlaces) set; Offset is computed and overwritten here.
+func NewWriter(w io.WriteSeeker, fields []Field, version byte) (*Writer, error) {
+	if _, ok := supportedVersions[version]; !ok {
+		return nil, fmt.Errorf("dbf: unsupported version: %#x", version)
+	}
+
+	laidOut := make([]Field, len(fields))
+	recordlen := uint16(1) // the deleted flag byte
+	for i, f := range fields {
+		if err := f.validate(); err != nil {
+			return nil, err
+		}
+		f.Offset = uint32(recordlen)
+		laidOut[i] = f
+		recordlen += uint16(f.Len)
+	}
+	headerlen := uint16(32 + 32*len(laidOut) + 1) // fixed header + one descriptor per field + 0x0D terminator
+
+	if _, err := w.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	h := header{
+		Version:   version,
+		Year:      uint8(now.Year() - 1900),
+		Month:     uint8(now.Month()),
+		Day:       uint8(now.Day()),
+		Headerlen: headerlen,
+		Recordlen: recordlen,
+	}
+	if err := binary.Write(w, binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+	for _, f := range laidOut {
+		if err := binary.Write(w, binary.LittleEndian, &f); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.Write([]byte{0x0D}); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, fields: laidOut, version: version, headerlen: headerlen, recordlen: recordlen}, nil
+}
+
+//OpenReadWrite opens an existing DBF file for both reading and appending.
+//The returned Writer continues numbering new records after the ones the
+//Reader already knows about, and updates Nrec in place on Close.
+func OpenReadWrite(rws io.ReadWriteSeeker) (*Reader, *Writer, error) {
+	r, err := NewReader(rws)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := make([]Field, len(r.fields))
+	for i, ff := range r.fields {
+		fields[i] = ff.Field
+	}
+	wtr := &Writer{
+		w:         rws,
+		fields:    fields,
+		version:   r.fileVersion,
+		headerlen: r.headerlen,
+		recordlen: r.recordlen,
+		nrec:      uint32(r.Length),
+	}
+	return r, wtr, nil
+}
+
+//Write encodes rec and appends it as the next record, in the inverse of
+//getFieldValueCasting: C is space padded, N is right-aligned with its
+//declared decimal places, D is YYYYMMDD, L is T/F, I is little-endian int32.
+func (wtr *Writer) Write(rec Record) error {
+	wtr.Lock()
+	defer wtr.Unlock()
+
+	buf := make([]byte, wtr.recordlen)
+	buf[0] = ' '
+	offset := 1
+	for _, f := range wtr.fields {
+		name := Tillzero(f.Name[:])
+		enc, err := encodeField(f, rec[name])
+		if err != nil {
+			return fmt.Errorf("dbf: field %s: %w", name, err)
+		}
+		if len(enc) != int(f.Len) {
+			return fmt.Errorf("dbf: field %s: encoded value is %d bytes, want %d", name, len(enc), f.Len)
+		}
+		copy(buf[offset:offset+int(f.Len)], enc)
+		offset += int(f.Len)
+	}
+
+	recordOffset := int64(wtr.headerlen) + int64(wtr.recordlen)*int64(wtr.nrec)
+	if _, err := wtr.w.Seek(recordOffset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := wtr.w.Write(buf); err != nil {
+		return err
+	}
+	wtr.nrec++
+	return nil
+}
+
+//WriteOrdered is Write for an OrderedRecord, matching values to fields
+//positionally in the same order ReadOrdered returns them.
+func (wtr *Writer) WriteOrdered(orec OrderedRecord) error {
+	if len(orec) != len(wtr.fields) {
+		return fmt.Errorf("dbf: WriteOrdered expected %d values, got %d", len(wtr.fields), len(orec))
+	}
+	rec := make(Record, len(wtr.fields))
+	for i, f := range wtr.fields {
+		rec[Tillzero(f.Name[:])] = orec[i]
+	}
+	return wtr.Write(rec)
+}
+
+//MarkDeleted flags record i as deleted without removing it; Pack physically
+//reclaims the space later.
+func (wtr *Writer) MarkDeleted(i int) error {
+	wtr.Lock()
+	defer wtr.Unlock()
+
+	if i < 0 || i >= int(wtr.nrec) {
+		return fmt.Errorf("dbf: record %d out of range (0-%d)", i, wtr.nrec-1)
+	}
+	offset := int64(wtr.headerlen) + int64(wtr.recordlen)*int64(i)
+	if _, err := wtr.w.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := wtr.w.Write([]byte{'*'})
+	return err
+}
+
+//Pack physically removes deleted records by shifting every record after
+//them down, and shrinks the record count accordingly. It requires the
+//Writer's underlying io.WriteSeeker to also implement io.Reader (as the
+//io.ReadWriteSeeker given to OpenReadWrite does) and returns an error
+//otherwise.
+func (wtr *Writer) Pack() error {
+	wtr.Lock()
+	defer wtr.Unlock()
+
+	rd, ok := wtr.w.(io.Reader)
+	if !ok {
+		return fmt.Errorf("dbf: Pack requires a readable file; open it with OpenReadWrite")
+	}
+
+	buf := make([]byte, wtr.recordlen)
+	writeIdx := int64(0)
+	for readIdx := int64(0); readIdx < int64(wtr.nrec); readIdx++ {
+		readOffset := int64(wtr.headerlen) + int64(wtr.recordlen)*readIdx
+		if _, err := wtr.w.Seek(readOffset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return err
+		}
+		if buf[0] == '*' {
+			continue
+		}
+		if writeIdx != readIdx {
+			writeOffset := int64(wtr.headerlen) + int64(wtr.recordlen)*writeIdx
+			if _, err := wtr.w.Seek(writeOffset, io.SeekStart); err != nil {
+				return err
+			}
+			if _, err := wtr.w.Write(buf); err != nil {
+				return err
+			}
+		}
+		writeIdx++
+	}
+	wtr.nrec = uint32(writeIdx)
+	return nil
+}
+
+//Close finalizes the header - record count, modification date - and writes
+//the 0x1A end-of-file marker after the last record.
+func (wtr *Writer) Close() error {
+	wtr.Lock()
+	defer wtr.Unlock()
+
+	now := time.Now()
+	if _, err := wtr.w.Seek(1, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := wtr.w.Write([]byte{uint8(now.Year() - 1900), uint8(now.Month()), uint8(now.Day())}); err != nil {
+		return err
+	}
+	if _, err := wtr.w.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(wtr.w, binary.LittleEndian, wtr.nrec); err != nil {
+		return err
+	}
+
+	eofOffset := int64(wtr.headerlen) + int64(wtr.recordlen)*int64(wtr.nrec)
+	if _, err := wtr.w.Seek(eofOffset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := wtr.w.Write([]byte{0x1A})
+	return err
+}
+
+//encodeField renders v into exactly f.Len bytes, the inverse of
+//getFieldValueCasting.
+func encodeField(f Field, v interface{}) ([]byte, error) {
+	switch f.Type {
+	case 'C', 'V':
+		if v == nil {
+			return padRight(nil, int(f.Len)), nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode %T as a string field", v)
+		}
+		if len(s) > int(f.Len) {
+			return nil, fmt.Errorf("value %q does not fit in C(%d)", s, f.Len)
+		}
+		return padRight([]byte(s), int(f.Len)), nil
+	case 'N':
+		return encodeNumeric(f, v)
+	case 'F':
+		if v == nil {
+			return padLeft(nil, int(f.Len)), nil
+		}
+		fv, err := toFloatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		s := strconv.FormatFloat(fv, 'f', -1, 64)
+		if len(s) > int(f.Len) {
+			return nil, fmt.Errorf("value %q does not fit in F(%d)", s, f.Len)
+		}
+		return padLeft([]byte(s), int(f.Len)), nil
+	case 'I':
+		iv, err := toIntValue(v)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, uint32(int32(iv)))
+		return buf, nil
+	case 'L':
+		return encodeLogical(v)
+	case 'D':
+		return encodeDate(v)
+	case 'T':
+		if _, ok := v.(time.Time); !ok && v != nil {
+			return nil, fmt.Errorf("cannot encode %T as a datetime", v)
+		}
+		return encodeDateTimeField(v), nil
+	case 'Y':
+		fv, err := toFloatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return encodeCurrencyField(fv), nil
+	case 'B':
+		fv, err := toFloatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return encodeDoubleField(fv), nil
+	default:
+		return nil, fmt.Errorf("dbf: Writer does not support encoding field type %q", f.Type)
+	}
+}
+
+func encodeNumeric(f Field, v interface{}) ([]byte, error) {
+	if v == nil {
+		return padLeft(nil, int(f.Len)), nil
+	}
+	var s string
+	if f.DecimalPlaces == 0 {
+		iv, err := toIntValue(v)
+		if err != nil {
+			return nil, err
+		}
+		s = strconv.Itoa(iv)
+	} else {
+		fv, err := toFloatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		s = strconv.FormatFloat(fv, 'f', int(f.DecimalPlaces), 64)
+	}
+	if len(s) > int(f.Len) {
+		return nil, fmt.Errorf("value %q does not fit in N(%d,%d)", s, f.Len, f.DecimalPlaces)
+	}
+	return padLeft([]byte(s), int(f.Len)), nil
+}
+
+func encodeLogical(v interface{}) ([]byte, error) {
+	switch b := v.(type) {
+	case bool:
+		if b {
+			return []byte{'T'}, nil
+		}
+		return []byte{'F'}, nil
+	case int32: // the rune getFieldValueCasting returns for 'L' ('T'/'F'/' ')
+		return []byte{byte(b)}, nil
+	case nil:
+		return []byte{' '}, nil
+	default:
+		return nil, fmt.Errorf("cannot encode %T as a logical field", v)
+	}
+}
+
+func encodeDate(v interface{}) ([]byte, error) {
+	if v == nil {
+		return []byte(strings.Repeat(" ", 8)), nil
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("cannot encode %T as a date", v)
+	}
+	return []byte(t.Format("20060102")), nil
+}
+
+//encodeDateTimeField is the inverse of decodeDateTime: 4 bytes of Julian
+//day number followed by 4 bytes of milliseconds since midnight.
+func encodeDateTimeField(v interface{}) []byte {
+	buf := make([]byte, 8)
+	t, ok := v.(time.Time)
+	if !ok {
+		return buf
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	ms := t.Sub(midnight).Milliseconds()
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(dateToJulianDay(t)))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(ms))
+	return buf
+}
+
+//dateToJulianDay converts a Gregorian calendar date to a Julian day number,
+//the inverse of julianDayToDate.
+func dateToJulianDay(t time.Time) int32 {
+	y, m, d := t.Date()
+	a := (14 - int(m)) / 12
+	y2 := y + 4800 - a
+	m2 := int(m) + 12*a - 3
+	jdn := d + (153*m2+2)/5 + 365*y2 + y2/4 - y2/100 + y2/400 - 32045
+	return int32(jdn)
+}
+
+//encodeCurrencyField is the inverse of decodeCurrency.
+func encodeCurrencyField(fv float64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(int64(fv*10000)))
+	return buf
+}
+
+//encodeDoubleField is the inverse of decodeDouble.
+func encodeDoubleField(fv float64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(fv))
+	return buf
+}
+
+func toIntValue(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	default:
+		return 0, fmt.Errorf("cannot encode %T as a number", v)
+	}
+}
+
+func toFloatValue(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	default:
+		return 0, fmt.Errorf("cannot encode %T as a number", v)
+	}
+}
+
+func padLeft(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	out := make([]byte, n)
+	for i := 0; i < n-len(b); i++ {
+		out[i] = ' '
+	}
+	copy(out[n-len(b):], b)
+	return out
+}
+
+func padRight(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	out := make([]byte, n)
+	copy(out, b)
+	for i := len(b); i < n; i++ {
+		out[i] = ' '
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package dbf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/roundtrip.dbf"
+	fields := []Field{
+		{Name: nameBytes("NAME"), Type: 'C', Len: 10},
+		{Name: nameBytes("AGE"), Type: 'N', Len: 5, DecimalPlaces: 0},
+		{Name: nameBytes("SCORE"), Type: 'N', Len: 8, DecimalPlaces: 2},
+		{Name: nameBytes("ACTIVE"), Type: 'L', Len: 1},
+		{Name: nameBytes("JOINED"), Type: 'D', Len: 8},
+	}
+	joined := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, fields, 0x03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := Record{
+		"NAME":   "carol",
+		"AGE":    41,
+		"SCORE":  98.5,
+		"ACTIVE": true,
+		"JOINED": joined,
+	}
+	if err := w.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	rf, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+	r, err := NewReader(rf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Length != 1 {
+		t.Fatalf("Length = %d, want 1", r.Length)
+	}
+
+	got, err := r.Read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["NAME"] != "carol" {
+		t.Errorf("NAME = %v, want carol", got["NAME"])
+	}
+	if got["AGE"] != 41 {
+		t.Errorf("AGE = %v, want 41", got["AGE"])
+	}
+	if got["SCORE"] != 98.5 {
+		t.Errorf("SCORE = %v, want 98.5", got["SCORE"])
+	}
+	if got["ACTIVE"] != int32('T') {
+		t.Errorf("ACTIVE = %v, want 'T'", got["ACTIVE"])
+	}
+	jt, ok := got["JOINED"].(time.Time)
+	if !ok || !jt.Equal(joined) {
+		t.Errorf("JOINED = %v, want %v", got["JOINED"], joined)
+	}
+}
+
+//TestWriterRejectsTypeMismatch is a regression test: encoding a value whose
+//Go type doesn't match the field's declared type must return an error
+//instead of silently writing a corrupt or placeholder value.
+func TestWriterRejectsTypeMismatch(t *testing.T) {
+	path := t.TempDir() + "/mismatch.dbf"
+	fields := []Field{{Name: nameBytes("NAME"), Type: 'C', Len: 10}}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := NewWriter(f, fields, 0x03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(Record{"NAME": 42}); err == nil {
+		t.Fatal("expected an error encoding an int into a C field, got nil")
+	}
+}
+
+func TestWriterRejectsLogicalTypeMismatch(t *testing.T) {
+	path := t.TempDir() + "/mismatch-logical.dbf"
+	fields := []Field{{Name: nameBytes("ACTIVE"), Type: 'L', Len: 1}}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := NewWriter(f, fields, 0x03)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(Record{"ACTIVE": "yes"}); err == nil {
+		t.Fatal("expected an error encoding a string into an L field, got nil")
+	}
+}